@@ -0,0 +1,190 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package acme adapts the active24 DNS record API to the go-acme/lego
+// challenge.Provider interface so that Active24 can be used as a DNS-01
+// solver.
+package acme
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// Config holds the configuration of the DNS-01 provider.
+type Config struct {
+	// ApiKey is the Active24 API key.
+	ApiKey string
+	// ApiSecret is the Active24 API secret.
+	ApiSecret string
+	// ApiEndpoint overrides the default Active24 REST API endpoint, if set.
+	ApiEndpoint string
+	// Ttl is the TTL (in seconds) used for the TXT records created by this provider.
+	Ttl int
+	// PropagationTimeout is the maximum time to wait for the DNS record to propagate.
+	PropagationTimeout time.Duration
+	// PollingInterval is the interval between propagation checks.
+	PollingInterval time.Duration
+	// DomainServiceIDs maps a zone (e.g. "example.com") to its Active24 service ID.
+	// It must contain an entry for the parent zone of every domain this provider
+	// is asked to solve a challenge for, since the Active24 API does not expose
+	// a way to discover the service ID of a zone by name.
+	DomainServiceIDs map[string]int
+}
+
+// NewDefaultConfig returns a Config populated with sane defaults.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Ttl:                600,
+		PropagationTimeout: 2 * time.Minute,
+		PollingInterval:    5 * time.Second,
+		DomainServiceIDs:   map[string]int{},
+	}
+}
+
+// DNSProvider implements challenge.Provider for the Active24 DNS API.
+type DNSProvider struct {
+	config *Config
+	client active24.Client
+}
+
+// NewDNSProvider returns a DNSProvider authenticated with apiKey/apiSecret and
+// using the default configuration.
+func NewDNSProvider(apiKey string, apiSecret string) (*DNSProvider, error) {
+	config := NewDefaultConfig()
+	config.ApiKey = apiKey
+	config.ApiSecret = apiSecret
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig returns a DNSProvider built from the given Config.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("active24: the configuration of the DNS provider is nil")
+	}
+	if config.ApiKey == "" || config.ApiSecret == "" {
+		return nil, fmt.Errorf("active24: ApiKey and ApiSecret are required")
+	}
+
+	var opts []active24.Option
+	if config.ApiEndpoint != "" {
+		opts = append(opts, active24.ApiEndpoint(config.ApiEndpoint))
+	}
+
+	return &DNSProvider{
+		config: config,
+		client: active24.New(config.ApiKey, config.ApiSecret, opts...),
+	}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record to fulfil the DNS-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := toChallenge(domain, keyAuth)
+
+	svcID, recName, err := d.resolveService(fqdn)
+	if err != nil {
+		return fmt.Errorf("active24: %w", err)
+	}
+
+	recType := string(active24.DnsRecordTypeTXT)
+	content := value
+	if err := d.client.Dns().With(svcID).Create(&active24.DnsRecord{
+		Type:    &recType,
+		Name:    recName,
+		Content: &content,
+		Ttl:     d.config.Ttl,
+	}); err != nil {
+		return fmt.Errorf("active24: failed to create TXT record %q: %w", fqdn, err.Error())
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record created by Present.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := toChallenge(domain, keyAuth)
+
+	svcID, recName, err := d.resolveService(fqdn)
+	if err != nil {
+		return fmt.Errorf("active24: %w", err)
+	}
+
+	actions := d.client.Dns().With(svcID)
+	recs, apiErr := actions.List(active24.DnsRecordTypeTXT, recName)
+	if apiErr != nil {
+		return fmt.Errorf("active24: failed to list TXT records %q: %w", fqdn, apiErr.Error())
+	}
+
+	for _, rec := range recs {
+		if rec.Content != nil && *rec.Content == value && rec.ID != nil {
+			if apiErr := actions.Delete(*rec.ID); apiErr != nil {
+				return fmt.Errorf("active24: failed to delete TXT record %q: %w", fqdn, apiErr.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveService returns the Active24 service ID of the parent zone of fqdn
+// together with the record name (relative to that zone) to use. When more
+// than one configured zone matches (e.g. both "example.com" and
+// "staging.example.com"), the most specific (longest) zone wins.
+func (d *DNSProvider) resolveService(fqdn string) (int, string, error) {
+	name := strings.TrimSuffix(fqdn, ".")
+
+	zones := make([]string, 0, len(d.config.DomainServiceIDs))
+	for zone := range d.config.DomainServiceIDs {
+		zones = append(zones, zone)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return len(strings.TrimSuffix(zones[i], ".")) > len(strings.TrimSuffix(zones[j], "."))
+	})
+
+	for _, zone := range zones {
+		trimmedZone := strings.TrimSuffix(zone, ".")
+		if name == trimmedZone || strings.HasSuffix(name, "."+trimmedZone) {
+			recName := strings.TrimSuffix(strings.TrimSuffix(name, trimmedZone), ".")
+			return d.config.DomainServiceIDs[zone], recName, nil
+		}
+	}
+	return 0, "", fmt.Errorf("no service ID configured for zone of %q, set Config.DomainServiceIDs", fqdn)
+}
+
+// toChallenge returns the _acme-challenge FQDN and the base64url/SHA-256 key
+// authorization digest for the given domain and keyAuth, as required by the
+// DNS-01 challenge (RFC 8555 section 8.4).
+func toChallenge(domain, keyAuth string) (fqdn string, value string) {
+	digest := sha256.Sum256([]byte(keyAuth))
+	value = base64.RawURLEncoding.EncodeToString(digest[:])
+	fqdn = fmt.Sprintf("_acme-challenge.%s.", strings.TrimSuffix(domain, "."))
+	return fqdn, value
+}
+
+var _ challenge.Provider = (*DNSProvider)(nil)
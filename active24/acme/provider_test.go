@@ -0,0 +1,149 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// fakeRecordServer is a minimal in-memory stand-in for the Active24 DNS
+// record API, enough to drive Present/CleanUp end-to-end over real HTTP.
+type fakeRecordServer struct {
+	mu      sync.Mutex
+	nextID  int
+	records map[int]active24.DnsRecord
+	srv     *httptest.Server
+}
+
+func newFakeRecordServer() *fakeRecordServer {
+	f := &fakeRecordServer{records: map[int]active24.DnsRecord{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeRecordServer) URL() string { return f.srv.URL }
+func (f *fakeRecordServer) Close()      { f.srv.Close() }
+
+func (f *fakeRecordServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet:
+		data := make([]active24.DnsRecord, 0, len(f.records))
+		for _, rec := range f.records {
+			data = append(data, rec)
+		}
+		page, pages := 1, 1
+		_ = json.NewEncoder(w).Encode(active24.DnsRecordPaginatedCollection{
+			CurrentPage: &page, TotalPages: &pages, Data: data,
+		})
+	case r.Method == http.MethodPost:
+		var rec active24.DnsRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		f.nextID++
+		id := f.nextID
+		rec.ID = &id
+		f.records[id] = rec
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		id := idFromPath(r.URL.Path)
+		delete(f.records, id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// idFromPath extracts the trailing record ID from a path of the form
+// "/v2/service/{svcID}/dns/record/{id}".
+func idFromPath(path string) int {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	id, _ := strconv.Atoi(segments[len(segments)-1])
+	return id
+}
+
+func (f *fakeRecordServer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.records)
+}
+
+func newTestProvider(t *testing.T, f *fakeRecordServer) *DNSProvider {
+	t.Helper()
+	config := NewDefaultConfig()
+	config.ApiKey = "key"
+	config.ApiSecret = "secret"
+	config.ApiEndpoint = f.URL()
+	config.DomainServiceIDs = map[string]int{"example.com": 1}
+	provider, err := NewDNSProviderConfig(config)
+	if err != nil {
+		t.Fatalf("NewDNSProviderConfig: %v", err)
+	}
+	return provider
+}
+
+func TestPresentCreatesTXTRecord(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	provider := newTestProvider(t, f)
+
+	if err := provider.Present("example.com", "token", "key-auth"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	if got := f.count(); got != 1 {
+		t.Fatalf("server has %d records, want 1", got)
+	}
+}
+
+func TestCleanUpRemovesTXTRecord(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	provider := newTestProvider(t, f)
+
+	if err := provider.Present("example.com", "token", "key-auth"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+	if got := f.count(); got != 1 {
+		t.Fatalf("server has %d records after Present, want 1", got)
+	}
+
+	if err := provider.CleanUp("example.com", "token", "key-auth"); err != nil {
+		t.Fatalf("CleanUp: %v", err)
+	}
+	if got := f.count(); got != 0 {
+		t.Fatalf("server has %d records after CleanUp, want 0", got)
+	}
+}
+
+func TestPresentUnknownZoneFails(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	provider := newTestProvider(t, f)
+
+	if err := provider.Present("unconfigured.com", "token", "key-auth"); err == nil {
+		t.Fatal("expected Present to fail for a domain with no configured service ID")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ApplyOptions controls how ApplyDesiredState executes the Plan it computes.
+type ApplyOptions struct {
+	// DryRun computes the Plan without applying it.
+	DryRun bool
+	// Parallelism is the number of creates/updates/deletes executed
+	// concurrently. Values <= 1 run the plan sequentially.
+	Parallelism int
+}
+
+// Plan is the set of changes ApplyDesiredState computed between the desired
+// records and the records currently present in the domain.
+type Plan struct {
+	Adds    []DnsRecord
+	Updates []DnsRecord
+	Deletes []DnsRecord
+}
+
+// Upsert creates r if no record of the same (type, name, content) exists
+// yet, or updates the existing one in place (TTL/priority/port/weight) if it
+// does, instead of creating a duplicate. It reports whether a new record was
+// created.
+func (d *domainAction) Upsert(r *DnsRecord) (bool, ApiError) {
+	return d.UpsertCtx(context.Background(), r)
+}
+
+func (d *domainAction) UpsertCtx(ctx context.Context, r *DnsRecord) (bool, ApiError) {
+	recType := DnsRecordType("")
+	if r.Type != nil {
+		recType = DnsRecordType(*r.Type)
+	}
+	existing, err := d.ListCtx(ctx, recType, r.Name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range existing {
+		if !recordMatches(e, *r) {
+			continue
+		}
+		if recordIdentical(e, *r) {
+			return false, nil
+		}
+		return false, d.UpdateCtx(ctx, *e.ID, r)
+	}
+	return true, d.CreateCtx(ctx, r)
+}
+
+// ApplyDesiredState reconciles desired against the records currently present
+// in the domain and returns the Plan it computed. Unless opts.DryRun is set,
+// the plan is also executed, creating/updating/deleting records with up to
+// opts.Parallelism concurrent requests.
+func (d *domainAction) ApplyDesiredState(desired []DnsRecord, opts ApplyOptions) (Plan, ApiError) {
+	return d.ApplyDesiredStateCtx(context.Background(), desired, opts)
+}
+
+func (d *domainAction) ApplyDesiredStateCtx(ctx context.Context, desired []DnsRecord, opts ApplyOptions) (Plan, ApiError) {
+	live, err := d.ListAllCtx(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := buildPlan(live, desired)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	var applyErr ApiError
+	if opts.Parallelism > 1 {
+		applyErr = runParallel(opts.Parallelism, len(plan.Adds), func(i int) ApiError {
+			return d.CreateCtx(ctx, &plan.Adds[i])
+		})
+	} else {
+		for i := range plan.Adds {
+			if applyErr = d.CreateCtx(ctx, &plan.Adds[i]); applyErr != nil {
+				return plan, applyErr
+			}
+		}
+	}
+	if applyErr != nil {
+		return plan, applyErr
+	}
+
+	if opts.Parallelism > 1 {
+		applyErr = runParallel(opts.Parallelism, len(plan.Updates), func(i int) ApiError {
+			return d.UpdateCtx(ctx, *plan.Updates[i].ID, &plan.Updates[i])
+		})
+	} else {
+		for i := range plan.Updates {
+			if applyErr = d.UpdateCtx(ctx, *plan.Updates[i].ID, &plan.Updates[i]); applyErr != nil {
+				return plan, applyErr
+			}
+		}
+	}
+	if applyErr != nil {
+		return plan, applyErr
+	}
+
+	if opts.Parallelism > 1 {
+		applyErr = runParallel(opts.Parallelism, len(plan.Deletes), func(i int) ApiError {
+			return d.DeleteCtx(ctx, *plan.Deletes[i].ID)
+		})
+	} else {
+		for i := range plan.Deletes {
+			if applyErr = d.DeleteCtx(ctx, *plan.Deletes[i].ID); applyErr != nil {
+				return plan, applyErr
+			}
+		}
+	}
+	return plan, applyErr
+}
+
+// DeleteWhere deletes every record for which filter returns true, returning
+// the number of records deleted.
+func (d *domainAction) DeleteWhere(filter func(DnsRecord) bool) (int, ApiError) {
+	return d.DeleteWhereCtx(context.Background(), filter)
+}
+
+func (d *domainAction) DeleteWhereCtx(ctx context.Context, filter func(DnsRecord) bool) (int, ApiError) {
+	records, err := d.ListAllCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, rec := range records {
+		if rec.ID == nil || !filter(rec) {
+			continue
+		}
+		if err := d.DeleteCtx(ctx, *rec.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// buildPlan compares live against desired, matching records by (type, name,
+// content), and returns the creates/updates/deletes needed to make live look
+// like desired.
+func buildPlan(live, desired []DnsRecord) Plan {
+	liveByKey := make(map[string]DnsRecord, len(live))
+	for _, rec := range live {
+		liveByKey[RecordKey(rec)] = rec
+	}
+	seen := make(map[string]bool, len(desired))
+
+	var plan Plan
+	for _, rec := range desired {
+		key := RecordKey(rec)
+		seen[key] = true
+		existing, ok := liveByKey[key]
+		if !ok {
+			plan.Adds = append(plan.Adds, rec)
+			continue
+		}
+		if !RecordsEqual(existing, rec) {
+			rec.ID = existing.ID
+			plan.Updates = append(plan.Updates, rec)
+		}
+	}
+	for key, rec := range liveByKey {
+		if !seen[key] {
+			plan.Deletes = append(plan.Deletes, rec)
+		}
+	}
+	return plan
+}
+
+func recordMatches(a, b DnsRecord) bool {
+	return RecordKey(a) == RecordKey(b)
+}
+
+func recordIdentical(a, b DnsRecord) bool {
+	return RecordsEqual(a, b)
+}
+
+// RecordKey identifies a record by (type, name, content): the identity
+// Upsert, ApplyDesiredState and the zone package's Import all use to decide
+// whether a desired record already exists or needs to be created.
+func RecordKey(rec DnsRecord) string {
+	recType := ""
+	if rec.Type != nil {
+		recType = *rec.Type
+	}
+	content := ""
+	if rec.Content != nil {
+		content = *rec.Content
+	}
+	return strings.ToUpper(recType) + "|" + strings.ToLower(rec.Name) + "|" + content
+}
+
+// RecordsEqual reports whether a and b (already matched on RecordKey) have
+// the same TTL, priority, port and weight, i.e. whether updating a to look
+// like b would be a no-op.
+func RecordsEqual(a, b DnsRecord) bool {
+	if a.Ttl != b.Ttl {
+		return false
+	}
+	if !intPtrEqual(a.Priority, b.Priority) {
+		return false
+	}
+	if !intPtrEqual(a.Port, b.Port) {
+		return false
+	}
+	if !intPtrEqual(a.Weight, b.Weight) {
+		return false
+	}
+	return true
+}
+
+func intPtrEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// runParallel runs fn(0..n-1) with at most parallelism concurrent
+// invocations, returning the first error encountered (if any), after all
+// invocations have completed.
+func runParallel(parallelism, n int, fn func(i int) ApiError) ApiError {
+	if n == 0 {
+		return nil
+	}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err.Error()
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		return nil
+	}
+	return apiErr(nil, fmt.Errorf("one or more operations failed: %w", firstErr))
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRecordServer is a minimal in-memory stand-in for the Active24 DNS
+// record API, enough to drive Upsert/ApplyDesiredState/DeleteWhere/zone
+// Import/Export end-to-end over real HTTP.
+type fakeRecordServer struct {
+	mu      sync.Mutex
+	nextID  int
+	records map[int]DnsRecord
+	srv     *httptest.Server
+}
+
+func newFakeRecordServer() *fakeRecordServer {
+	f := &fakeRecordServer{records: map[int]DnsRecord{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeRecordServer) URL() string { return f.srv.URL }
+func (f *fakeRecordServer) Close()      { f.srv.Close() }
+
+func (f *fakeRecordServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet:
+		data := make([]DnsRecord, 0, len(f.records))
+		for _, rec := range f.records {
+			data = append(data, rec)
+		}
+		page, pages := 1, 1
+		_ = json.NewEncoder(w).Encode(DnsRecordPaginatedCollection{
+			CurrentPage: &page, TotalPages: &pages, Data: data,
+		})
+	case r.Method == http.MethodPost:
+		var rec DnsRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		f.nextID++
+		id := f.nextID
+		rec.ID = &id
+		f.records[id] = rec
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		id := idFromPath(r.URL.Path)
+		var rec DnsRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		rec.ID = &id
+		f.records[id] = rec
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		id := idFromPath(r.URL.Path)
+		delete(f.records, id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// idFromPath extracts the trailing record ID from a path of the form
+// "/v2/service/{svcID}/dns/record/{id}".
+func idFromPath(path string) int {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	id, _ := strconv.Atoi(segments[len(segments)-1])
+	return id
+}
+
+func (f *fakeRecordServer) seed(recs ...DnsRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, rec := range recs {
+		f.nextID++
+		id := f.nextID
+		rec.ID = &id
+		f.records[id] = rec
+	}
+}
+
+func (f *fakeRecordServer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.records)
+}
+
+func newTestActions(t *testing.T, f *fakeRecordServer) DnsRecordActions {
+	t.Helper()
+	c := New("key", "secret", ApiEndpoint(f.URL()))
+	return c.Dns().With(1)
+}
+
+func TestUpsertCreatesThenUpdatesInPlace(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	actions := newTestActions(t, f)
+
+	typ := string(DnsRecordTypeA)
+	content := "1.2.3.4"
+	created, apiErr := actions.Upsert(&DnsRecord{Type: &typ, Name: "www", Content: &content, Ttl: 300})
+	if apiErr != nil {
+		t.Fatalf("Upsert (create): %v", apiErr.Error())
+	}
+	if !created {
+		t.Fatal("expected Upsert to report a new record was created")
+	}
+	if got := f.count(); got != 1 {
+		t.Fatalf("server has %d records, want 1", got)
+	}
+
+	created, apiErr = actions.Upsert(&DnsRecord{Type: &typ, Name: "www", Content: &content, Ttl: 600})
+	if apiErr != nil {
+		t.Fatalf("Upsert (update): %v", apiErr.Error())
+	}
+	if created {
+		t.Fatal("expected second Upsert to update the existing record, not create a new one")
+	}
+	if got := f.count(); got != 1 {
+		t.Fatalf("server has %d records after update, want still 1", got)
+	}
+}
+
+func TestApplyDesiredStateReconciles(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	typ := string(DnsRecordTypeA)
+	stale := "9.9.9.9"
+	f.seed(DnsRecord{Type: &typ, Name: "stale", Content: &stale, Ttl: 300})
+	actions := newTestActions(t, f)
+
+	content := "1.2.3.4"
+	plan, apiErr := actions.ApplyDesiredState([]DnsRecord{
+		{Type: &typ, Name: "www", Content: &content, Ttl: 300},
+	}, ApplyOptions{})
+	if apiErr != nil {
+		t.Fatalf("ApplyDesiredState: %v", apiErr.Error())
+	}
+	if len(plan.Adds) != 1 {
+		t.Fatalf("plan.Adds = %+v, want 1 add", plan.Adds)
+	}
+	if len(plan.Deletes) != 1 {
+		t.Fatalf("plan.Deletes = %+v, want the stale record deleted", plan.Deletes)
+	}
+
+	live, apiErr := actions.ListAll()
+	if apiErr != nil {
+		t.Fatalf("ListAll: %v", apiErr.Error())
+	}
+	if len(live) != 1 || live[0].Name != "www" {
+		t.Fatalf("live records = %+v, want only the desired \"www\" record", live)
+	}
+}
+
+func TestDeleteWhereRemovesMatching(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	typ := string(DnsRecordTypeA)
+	c1, c2 := "1.1.1.1", "2.2.2.2"
+	f.seed(
+		DnsRecord{Type: &typ, Name: "a", Content: &c1, Ttl: 300},
+		DnsRecord{Type: &typ, Name: "b", Content: &c2, Ttl: 300},
+	)
+	actions := newTestActions(t, f)
+
+	deleted, apiErr := actions.DeleteWhere(func(rec DnsRecord) bool {
+		return rec.Name == "a"
+	})
+	if apiErr != nil {
+		t.Fatalf("DeleteWhere: %v", apiErr.Error())
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted = %d, want 1", deleted)
+	}
+	if got := f.count(); got != 1 {
+		t.Fatalf("server has %d records, want 1", got)
+	}
+}
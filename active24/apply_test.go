@@ -0,0 +1,116 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import "testing"
+
+func aRecord(id int, name, content string, ttl int) DnsRecord {
+	t := string(DnsRecordTypeA)
+	c := content
+	return DnsRecord{ID: &id, Type: &t, Name: name, Content: &c, Ttl: ttl}
+}
+
+func TestBuildPlanCreatesMissingRecord(t *testing.T) {
+	desired := []DnsRecord{aRecord(0, "www", "1.2.3.4", 300)}
+	plan := buildPlan(nil, desired)
+
+	if len(plan.Adds) != 1 || len(plan.Updates) != 0 || len(plan.Deletes) != 0 {
+		t.Fatalf("plan = %+v, want exactly one Add", plan)
+	}
+}
+
+func TestBuildPlanLeavesIdenticalRecordAlone(t *testing.T) {
+	live := []DnsRecord{aRecord(1, "www", "1.2.3.4", 300)}
+	desired := []DnsRecord{aRecord(0, "www", "1.2.3.4", 300)}
+	plan := buildPlan(live, desired)
+
+	if len(plan.Adds) != 0 || len(plan.Updates) != 0 || len(plan.Deletes) != 0 {
+		t.Fatalf("plan = %+v, want no changes", plan)
+	}
+}
+
+func TestBuildPlanUpdatesChangedTtl(t *testing.T) {
+	live := []DnsRecord{aRecord(1, "www", "1.2.3.4", 300)}
+	desired := []DnsRecord{aRecord(0, "www", "1.2.3.4", 600)}
+	plan := buildPlan(live, desired)
+
+	if len(plan.Adds) != 0 || len(plan.Deletes) != 0 {
+		t.Fatalf("plan = %+v, want only an Update", plan)
+	}
+	if len(plan.Updates) != 1 {
+		t.Fatalf("plan.Updates = %+v, want exactly one entry", plan.Updates)
+	}
+	if plan.Updates[0].ID == nil || *plan.Updates[0].ID != 1 {
+		t.Errorf("Updates[0].ID = %v, want the live record's ID (1)", plan.Updates[0].ID)
+	}
+	if plan.Updates[0].Ttl != 600 {
+		t.Errorf("Updates[0].Ttl = %d, want 600", plan.Updates[0].Ttl)
+	}
+}
+
+func TestBuildPlanDeletesRecordNotInDesired(t *testing.T) {
+	live := []DnsRecord{aRecord(1, "stale", "1.2.3.4", 300)}
+	plan := buildPlan(live, nil)
+
+	if len(plan.Adds) != 0 || len(plan.Updates) != 0 {
+		t.Fatalf("plan = %+v, want only a Delete", plan)
+	}
+	if len(plan.Deletes) != 1 || plan.Deletes[0].Name != "stale" {
+		t.Fatalf("plan.Deletes = %+v, want the stale record", plan.Deletes)
+	}
+}
+
+func TestBuildPlanMatchesOnTypeNameContent(t *testing.T) {
+	// Same name, different content: not a match, so this is an Add + Delete
+	// rather than an Update.
+	live := []DnsRecord{aRecord(1, "www", "1.2.3.4", 300)}
+	desired := []DnsRecord{aRecord(0, "www", "5.6.7.8", 300)}
+	plan := buildPlan(live, desired)
+
+	if len(plan.Updates) != 0 {
+		t.Fatalf("plan.Updates = %+v, want no updates for a content change", plan.Updates)
+	}
+	if len(plan.Adds) != 1 || len(plan.Deletes) != 1 {
+		t.Fatalf("plan = %+v, want one Add and one Delete", plan)
+	}
+}
+
+func TestRecordKeyIsCaseInsensitiveOnTypeAndName(t *testing.T) {
+	upper := string(DnsRecordTypeA)
+	lower := "a"
+	content := "1.2.3.4"
+	a := DnsRecord{Type: &lower, Name: "WWW", Content: &content}
+	b := DnsRecord{Type: &upper, Name: "www", Content: &content}
+
+	if RecordKey(a) != RecordKey(b) {
+		t.Errorf("RecordKey(%+v) != RecordKey(%+v), want equal keys", a, b)
+	}
+}
+
+func TestRecordsEqualComparesTtlAndOptionalFields(t *testing.T) {
+	prio1, prio2 := 10, 20
+	a := DnsRecord{Ttl: 300, Priority: &prio1}
+	b := DnsRecord{Ttl: 300, Priority: &prio1}
+	c := DnsRecord{Ttl: 300, Priority: &prio2}
+
+	if !RecordsEqual(a, b) {
+		t.Error("expected records with identical fields to be equal")
+	}
+	if RecordsEqual(a, c) {
+		t.Error("expected records with different Priority to be unequal")
+	}
+}
@@ -17,14 +17,19 @@ limitations under the License.
 package active24
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -65,6 +70,7 @@ func New(apiKey string, apiSecret string, opts ...Option) Client {
 			},
 			l:        klog.NewKlogr(),
 			maxPages: 100, // default max pages to prevent infinite loops
+			retry:    DefaultRetryPolicy(),
 		},
 	}
 	for _, opt := range opts {
@@ -103,6 +109,18 @@ type helper struct {
 	c           http.Client
 	l           klog.Logger
 	maxPages    int
+	retry       RetryPolicy
+	limiter     *rateLimiter
+	breaker     *circuitBreaker
+	metrics     *metricsCollector
+	tracer      func(TraceEvent)
+	wireDebug   bool
+}
+
+func (ch *helper) trace(ev TraceEvent) {
+	if ch.tracer != nil {
+		ch.tracer(ev)
+	}
 }
 
 func (ch *helper) getSignature(message, key string) string {
@@ -112,16 +130,105 @@ func (ch *helper) getSignature(message, key string) string {
 }
 
 func (ch *helper) do(reqMethod string, reqPath string, reqBody io.Reader) (*http.Response, error) {
-	return ch.doWithParams(reqMethod, reqPath, nil, reqBody)
+	return ch.doWithParamsCtx(context.Background(), reqMethod, reqPath, nil, reqBody)
+}
+
+func (ch *helper) doCtx(ctx context.Context, reqMethod string, reqPath string, reqBody io.Reader) (*http.Response, error) {
+	return ch.doWithParamsCtx(ctx, reqMethod, reqPath, nil, reqBody)
 }
 
 func (ch *helper) doWithParams(reqMethod string, reqPath string, reqParams url.Values, reqBody io.Reader) (*http.Response, error) {
+	return ch.doWithParamsCtx(context.Background(), reqMethod, reqPath, reqParams, reqBody)
+}
+
+// doWithParamsCtx runs a single logical API call through the retry/rate-limit/
+// circuit-breaker middleware chain, honoring ctx cancellation throughout.
+func (ch *helper) doWithParamsCtx(ctx context.Context, reqMethod string, reqPath string, reqParams url.Values, reqBody io.Reader) (*http.Response, error) {
 	reqPath = path.Join("/", reqPath)
+
+	// Buffer the body up front so it can be replayed on every retry attempt.
+	var bodyBytes []byte
+	if reqBody != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(reqBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := endpointTemplate(strings.TrimPrefix(reqPath, "/"))
+	attempts := ch.retry.MaxRetries + 1
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ch.breaker != nil && !ch.breaker.allow() {
+			return nil, errCircuitOpen
+		}
+		if ch.limiter != nil {
+			if wErr := ch.limiter.Wait(ctx); wErr != nil {
+				return nil, wErr
+			}
+		}
+
+		var bodyReader io.Reader
+		if bodyBytes != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+
+		ch.trace(TraceEvent{Kind: TraceRequestStart, Method: reqMethod, Path: reqPath, Attempt: attempt})
+		start := time.Now()
+		resp, err = ch.doOnce(ctx, reqMethod, reqPath, reqParams, bodyReader)
+		elapsed := time.Since(start)
+
+		status := ""
+		if resp != nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		ch.metrics.observeRequest(reqMethod, endpoint, status, elapsed)
+		ch.trace(TraceEvent{Kind: TraceRequestEnd, Method: reqMethod, Path: reqPath, Attempt: attempt, StatusCode: statusCodeOf(resp), Err: err, Duration: elapsed})
+
+		retryable := err != nil || isRetryableStatus(resp.StatusCode)
+		if ch.breaker != nil {
+			if retryable {
+				ch.breaker.recordFailure()
+			} else {
+				ch.breaker.recordSuccess()
+			}
+		}
+		if !retryable || attempt == attempts-1 {
+			return resp, err
+		}
+
+		delay := backoff(ch.retry, attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp); ok {
+				delay = ra
+			}
+			_ = resp.Body.Close()
+		}
+		ch.metrics.observeRetry(reqMethod, endpoint)
+		ch.trace(TraceEvent{Kind: TraceRetry, Method: reqMethod, Path: reqPath, Attempt: attempt + 1, Duration: delay})
+		ch.l.V(3).Info("Retrying API call", "method", reqMethod, "path", reqPath, "attempt", attempt+1, "delay", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return resp, err
+}
+
+// doOnce performs a single HTTP round-trip, without any retry/backoff logic.
+func (ch *helper) doOnce(ctx context.Context, reqMethod string, reqPath string, reqParams url.Values, reqBody io.Reader) (*http.Response, error) {
 	reqTimestamp := time.Now()
 	canonicalRequest := fmt.Sprintf("%s %s %d", reqMethod, reqPath, reqTimestamp.Unix())
 	authSignature := ch.getSignature(canonicalRequest, ch.authSecret)
 
-	r, err := http.NewRequest(reqMethod, fmt.Sprintf("%s%s", ch.apiEndpoint, reqPath), reqBody)
+	r, err := http.NewRequestWithContext(ctx, reqMethod, fmt.Sprintf("%s%s", ch.apiEndpoint, reqPath), reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -132,25 +239,30 @@ func (ch *helper) doWithParams(reqMethod string, reqPath string, reqParams url.V
 	r.SetBasicAuth(ch.authKey, authSignature)
 	ch.l.V(4).Info("Calling API", "method", reqMethod, "URL", r.URL.String())
 
-	// Log the request
-	/* dumpReq, dumpErr := httputil.DumpRequestOut(r, true)
-	if dumpErr != nil {
-		return nil, dumpErr
+	if ch.wireDebug && ch.l.V(5).Enabled() {
+		if dumpReq, dumpErr := httputil.DumpRequestOut(r, true); dumpErr == nil {
+			ch.l.V(5).Info("doOnce", "REQUEST", string(dumpReq))
+		}
 	}
-	ch.l.V(4).Info("doWithParams", "REQUEST", string(dumpReq)) */
 
 	resp, err := ch.c.Do(r)
 
-	// Log the response
-	/* dumpResp, dumpErr := httputil.DumpResponse(resp, true)
-	if dumpErr != nil {
-		return nil, dumpErr
+	if ch.wireDebug && ch.l.V(5).Enabled() && resp != nil {
+		if dumpResp, dumpErr := httputil.DumpResponse(resp, true); dumpErr == nil {
+			ch.l.V(5).Info("doOnce", "RESPONSE", string(dumpResp))
+		}
 	}
-	ch.l.V(4).Info("doWithParams", "RESPONSE", string(dumpResp)) */
 
 	return resp, err
 }
 
+func statusCodeOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
 func apiErr(resp *http.Response, err error) ApiError {
 	if err == nil {
 		return nil
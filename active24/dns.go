@@ -18,6 +18,7 @@ package active24
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -77,16 +78,43 @@ type DnsRecordPaginatedCollection struct {
 type DnsRecordActions interface {
 	// Create creates a new DNS record
 	Create(*DnsRecord) ApiError
+	// CreateCtx is like Create but honors ctx cancellation/deadline.
+	CreateCtx(context.Context, *DnsRecord) ApiError
 	// List lists all DNS records in this domain.
 	ListAll() ([]DnsRecord, ApiError)
+	// ListAllCtx is like ListAll but honors ctx cancellation/deadline.
+	ListAllCtx(context.Context) ([]DnsRecord, ApiError)
 	// List lists DNS records of specified type or name in this domain.
 	List(DnsRecordType, string) ([]DnsRecord, ApiError)
+	// ListCtx is like List but honors ctx cancellation/deadline.
+	ListCtx(context.Context, DnsRecordType, string) ([]DnsRecord, ApiError)
 	// ListPage lists 1 page of DNS records of specified type or name in this domain.
 	ListPage(DnsRecordType, string, string, int) ([]DnsRecord, string, int, ApiError)
+	// ListPageCtx is like ListPage but honors ctx cancellation/deadline.
+	ListPageCtx(context.Context, DnsRecordType, string, string, int) ([]DnsRecord, string, int, ApiError)
 	// Update updates an existing DNS record
 	Update(int, *DnsRecord) ApiError
+	// UpdateCtx is like Update but honors ctx cancellation/deadline.
+	UpdateCtx(context.Context, int, *DnsRecord) ApiError
 	// Delete removes single DNS record based on its ID
 	Delete(int) ApiError
+	// DeleteCtx is like Delete but honors ctx cancellation/deadline.
+	DeleteCtx(context.Context, int) ApiError
+	// Upsert creates r, or updates it in place if a record with the same
+	// (type, name, content) already exists, reporting whether it was created.
+	Upsert(r *DnsRecord) (created bool, err ApiError)
+	// UpsertCtx is like Upsert but honors ctx cancellation/deadline.
+	UpsertCtx(ctx context.Context, r *DnsRecord) (created bool, err ApiError)
+	// ApplyDesiredState reconciles desired against the records currently
+	// present in the domain and returns the Plan it computed (and, unless
+	// ApplyOptions.DryRun is set, executed).
+	ApplyDesiredState(desired []DnsRecord, opts ApplyOptions) (Plan, ApiError)
+	// ApplyDesiredStateCtx is like ApplyDesiredState but honors ctx cancellation/deadline.
+	ApplyDesiredStateCtx(ctx context.Context, desired []DnsRecord, opts ApplyOptions) (Plan, ApiError)
+	// DeleteWhere deletes every record matching filter, returning the number deleted.
+	DeleteWhere(filter func(DnsRecord) bool) (int, ApiError)
+	// DeleteWhereCtx is like DeleteWhere but honors ctx cancellation/deadline.
+	DeleteWhereCtx(ctx context.Context, filter func(DnsRecord) bool) (int, ApiError)
 }
 
 type dns struct {
@@ -106,10 +134,18 @@ type domainAction struct {
 }
 
 func (d *domainAction) ListAll() ([]DnsRecord, ApiError) {
-	return d.List("", "")
+	return d.ListAllCtx(context.Background())
+}
+
+func (d *domainAction) ListAllCtx(ctx context.Context) ([]DnsRecord, ApiError) {
+	return d.ListCtx(ctx, "", "")
 }
 
 func (d *domainAction) List(recType DnsRecordType, recName string) ([]DnsRecord, ApiError) {
+	return d.ListCtx(context.Background(), recType, recName)
+}
+
+func (d *domainAction) ListCtx(ctx context.Context, recType DnsRecordType, recName string) ([]DnsRecord, ApiError) {
 	var allRecords []DnsRecord
 	var nextPageUrl string
 	var nextPage int
@@ -118,13 +154,16 @@ func (d *domainAction) List(recType DnsRecordType, recName string) ([]DnsRecord,
 	pageCount := 1
 	for (pageCount == 1 || nextPageUrl != "" || nextPage > 0) && pageCount <= d.h.maxPages {
 		var pageRecords []DnsRecord
-		pageRecords, nextPageUrl, nextPage, err = d.ListPage(recType, recName, nextPageUrl, nextPage)
+		pageRecords, nextPageUrl, nextPage, err = d.ListPageCtx(ctx, recType, recName, nextPageUrl, nextPage)
 		if err != nil {
 			return nil, err
 		}
 		allRecords = append(allRecords, pageRecords...)
 		pageCount++
 	}
+	endpoint := endpointTemplate(fmt.Sprintf("v2/service/%d/dns/record", d.svcID))
+	d.h.metrics.observePages(http.MethodGet, endpoint, pageCount-1)
+	d.h.trace(TraceEvent{Kind: TracePageFetched, Method: http.MethodGet, Path: endpoint, Attempt: pageCount - 1})
 	if pageCount > d.h.maxPages && (nextPageUrl != "" || nextPage > d.h.maxPages) {
 		return allRecords, apiErr(nil, fmt.Errorf("maximum page limit reached in List, partial result returned, maxPages: %d, increase the limit in the configuration", d.h.maxPages))
 	}
@@ -132,7 +171,11 @@ func (d *domainAction) List(recType DnsRecordType, recName string) ([]DnsRecord,
 }
 
 func (d *domainAction) ListPage(recType DnsRecordType, recName string, recPageUrl string, recPage int) ([]DnsRecord, string, int, ApiError) {
-	ret, err := d.ListPaginated(recType, recName, recPageUrl, recPage)
+	return d.ListPageCtx(context.Background(), recType, recName, recPageUrl, recPage)
+}
+
+func (d *domainAction) ListPageCtx(ctx context.Context, recType DnsRecordType, recName string, recPageUrl string, recPage int) ([]DnsRecord, string, int, ApiError) {
+	ret, err := d.ListPaginated(ctx, recType, recName, recPageUrl, recPage)
 	if err != nil {
 		return nil, "", 0, err
 	}
@@ -152,7 +195,7 @@ func (d *domainAction) ListPage(recType DnsRecordType, recName string, recPageUr
 	return ret.Data, nextPageUrl, nextPage, err
 }
 
-func (d *domainAction) ListPaginated(recType DnsRecordType, recName string, recPageUrl string, recPage int) (DnsRecordPaginatedCollection, ApiError) {
+func (d *domainAction) ListPaginated(ctx context.Context, recType DnsRecordType, recName string, recPageUrl string, recPage int) (DnsRecordPaginatedCollection, ApiError) {
 	// HTTP request params
 	reqParams := url.Values{}
 	reqParams.Add("descending", "false")
@@ -184,7 +227,7 @@ func (d *domainAction) ListPaginated(recType DnsRecordType, recName string, recP
 		reqParams.Add("page", strconv.Itoa(recPage))
 	}
 
-	resp, err := d.h.doWithParams(http.MethodGet, fmt.Sprintf("v2/service/%d/dns/record", d.svcID), reqParams, nil)
+	resp, err := d.h.doWithParamsCtx(ctx, http.MethodGet, fmt.Sprintf("v2/service/%d/dns/record", d.svcID), reqParams, nil)
 	if err != nil {
 		return ret, apiErr(nil, err)
 	}
@@ -207,25 +250,37 @@ func (d *domainAction) ListPaginated(recType DnsRecordType, recName string, recP
 }
 
 func (d *domainAction) Create(r *DnsRecord) ApiError {
+	return d.CreateCtx(context.Background(), r)
+}
+
+func (d *domainAction) CreateCtx(ctx context.Context, r *DnsRecord) ApiError {
 	data, err := json.Marshal(r)
 	if err != nil {
 		return apiErr(nil, err)
 	}
-	return apiErr(d.h.do(http.MethodPost, fmt.Sprintf("v2/service/%d/dns/record", d.svcID), bytes.NewBuffer(data)))
+	return apiErr(d.h.doCtx(ctx, http.MethodPost, fmt.Sprintf("v2/service/%d/dns/record", d.svcID), bytes.NewBuffer(data)))
 }
 
-func (d *domainAction) change(method string, recordID int, r *DnsRecord) (*http.Response, error) {
+func (d *domainAction) change(ctx context.Context, method string, recordID int, r *DnsRecord) (*http.Response, error) {
 	data, err := json.Marshal(r)
 	if err != nil {
 		return nil, err
 	}
-	return d.h.do(method, fmt.Sprintf("v2/service/%d/dns/record/%d", d.svcID, recordID), bytes.NewBuffer(data))
+	return d.h.doCtx(ctx, method, fmt.Sprintf("v2/service/%d/dns/record/%d", d.svcID, recordID), bytes.NewBuffer(data))
 }
 
 func (d *domainAction) Update(ID int, r *DnsRecord) ApiError {
-	return apiErr(d.change(http.MethodPut, ID, r))
+	return d.UpdateCtx(context.Background(), ID, r)
+}
+
+func (d *domainAction) UpdateCtx(ctx context.Context, ID int, r *DnsRecord) ApiError {
+	return apiErr(d.change(ctx, http.MethodPut, ID, r))
 }
 
 func (d *domainAction) Delete(ID int) ApiError {
-	return apiErr(d.h.do(http.MethodDelete, fmt.Sprintf("v2/service/%d/dns/record/%d", d.svcID, ID), nil))
+	return d.DeleteCtx(context.Background(), ID)
+}
+
+func (d *domainAction) DeleteCtx(ctx context.Context, ID int) ApiError {
+	return apiErr(d.h.doCtx(ctx, http.MethodDelete, fmt.Sprintf("v2/service/%d/dns/record/%d", d.svcID, ID), nil))
 }
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// toRR converts an active24.DnsRecord into the dns.RR this package answers
+// queries with, qualifying its owner name and any target name under origin.
+func toRR(rec active24.DnsRecord, origin string) (dns.RR, error) {
+	name := absoluteName(rec.Name, origin)
+	hdr := dns.RR_Header{Name: name, Class: dns.ClassINET, Ttl: uint32(rec.Ttl)}
+	content := ""
+	if rec.Content != nil {
+		content = *rec.Content
+	}
+
+	switch recType(rec) {
+	case string(active24.DnsRecordTypeA):
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid A record content %q for %s", content, rec.Name)
+		}
+		hdr.Rrtype = dns.TypeA
+		return &dns.A{Hdr: hdr, A: ip.To4()}, nil
+	case string(active24.DnsRecordTypeAAAA):
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid AAAA record content %q for %s", content, rec.Name)
+		}
+		hdr.Rrtype = dns.TypeAAAA
+		return &dns.AAAA{Hdr: hdr, AAAA: ip}, nil
+	case string(active24.DnsRecordTypeCNAME):
+		hdr.Rrtype = dns.TypeCNAME
+		return &dns.CNAME{Hdr: hdr, Target: absoluteTarget(content, origin)}, nil
+	case string(active24.DnsRecordTypeANAME):
+		// ANAME is Active24-specific flattened CNAME at the zone apex; the
+		// nearest standard equivalent a resolver understands is a CNAME.
+		hdr.Rrtype = dns.TypeCNAME
+		return &dns.CNAME{Hdr: hdr, Target: absoluteTarget(content, origin)}, nil
+	case string(active24.DnsRecordTypeNS):
+		hdr.Rrtype = dns.TypeNS
+		return &dns.NS{Hdr: hdr, Ns: absoluteTarget(content, origin)}, nil
+	case string(active24.DnsRecordTypeMX):
+		hdr.Rrtype = dns.TypeMX
+		return &dns.MX{Hdr: hdr, Preference: uint16(intVal(rec.Priority)), Mx: absoluteTarget(content, origin)}, nil
+	case string(active24.DnsRecordTypeSRV):
+		hdr.Rrtype = dns.TypeSRV
+		return &dns.SRV{
+			Hdr: hdr, Priority: uint16(intVal(rec.Priority)), Weight: uint16(intVal(rec.Weight)),
+			Port: uint16(intVal(rec.Port)), Target: absoluteTarget(content, origin),
+		}, nil
+	case string(active24.DnsRecordTypeTXT):
+		hdr.Rrtype = dns.TypeTXT
+		return &dns.TXT{Hdr: hdr, Txt: splitTXT(content)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported record type %q for %s", recType(rec), rec.Name)
+	}
+}
+
+func intVal(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func absoluteName(name, origin string) string {
+	origin = dns.Fqdn(origin)
+	if name == "" || name == "@" {
+		return origin
+	}
+	return dns.Fqdn(name + "." + strings.TrimSuffix(origin, "."))
+}
+
+func absoluteTarget(target, origin string) string {
+	if strings.HasSuffix(target, ".") {
+		return target
+	}
+	return absoluteName(target, origin)
+}
+
+// splitTXT breaks a flattened TXT content string into ≤255 byte chunks, as a
+// TXT RR requires. The chunk boundaries are fixed at every 255 bytes; where
+// the content was originally stored as several shorter strings, those
+// boundaries are not restored.
+func splitTXT(content string) []string {
+	const max = 255
+	var out []string
+	for len(content) > 0 {
+		n := max
+		if n > len(content) {
+			n = len(content)
+		}
+		out = append(out, content[:n])
+		content = content[n:]
+	}
+	if out == nil {
+		out = []string{""}
+	}
+	return out
+}
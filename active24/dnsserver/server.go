@@ -0,0 +1,296 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnsserver exposes the records of a zone managed via
+// active24.DnsRecordActions as a read-only authoritative DNS server,
+// supporting AXFR zone transfers to secondaries. It is intended for
+// hidden-primary setups where Active24 is authoritative for storage but an
+// operator's own resolvers serve queries.
+package dnsserver
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/hostalp/active24-go/active24"
+	"k8s.io/klog/v2"
+)
+
+// Option configures a Server.
+type Option func(s *Server)
+
+// WithRefreshInterval sets how often the in-memory cache is refreshed from
+// the Active24 API. Defaults to 5 minutes.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.refreshInterval = d
+	}
+}
+
+// WithLogger overrides the logger used by the server. Defaults to klog.
+func WithLogger(l klog.Logger) Option {
+	return func(s *Server) {
+		s.l = l
+	}
+}
+
+// WithAllowedTransfer restricts which remote addresses (host, no port) may
+// perform an AXFR. If unset, AXFR is allowed from any address.
+func WithAllowedTransfer(addrs ...string) Option {
+	return func(s *Server) {
+		s.allowedTransfer = make(map[string]bool, len(addrs))
+		for _, a := range addrs {
+			s.allowedTransfer[a] = true
+		}
+	}
+}
+
+// Server answers DNS queries and AXFR requests for a single zone, backed by
+// records read from active24.DnsRecordActions.
+type Server struct {
+	actions active24.DnsRecordActions
+	origin  string
+
+	refreshInterval time.Duration
+	l               klog.Logger
+	allowedTransfer map[string]bool
+
+	mu     sync.RWMutex
+	byName map[string][]dns.RR
+	serial uint32
+	hash   uint64
+
+	notifyCh chan struct{}
+}
+
+// New returns a Server that serves origin using actions as its record
+// source.
+func New(actions active24.DnsRecordActions, origin string, opts ...Option) *Server {
+	s := &Server{
+		actions:         actions,
+		origin:          dns.Fqdn(origin),
+		refreshInterval: 5 * time.Minute,
+		l:               klog.NewKlogr(),
+		byName:          map[string][]dns.RR{},
+		notifyCh:        make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Refresh re-fetches every record of the zone from the Active24 API and
+// rebuilds the in-memory cache used to answer queries.
+func (s *Server) Refresh(ctx context.Context) error {
+	records, err := s.actions.ListAllCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("active24/dnsserver: failed to refresh zone %q: %w", s.origin, err.Error())
+	}
+
+	byName := map[string][]dns.RR{}
+	h := fnv.New64a()
+	for _, rec := range sortedRecords(records) {
+		rr, err := toRR(rec, s.origin)
+		if err != nil {
+			s.l.V(2).Info("skipping unsupported record", "name", rec.Name, "error", err)
+			continue
+		}
+		key := dns.Fqdn(rr.Header().Name)
+		byName[key] = append(byName[key], rr)
+		_, _ = h.Write([]byte(rr.String()))
+	}
+	newHash := h.Sum64()
+
+	s.mu.Lock()
+	if s.serial == 0 {
+		s.serial = uint32(time.Now().Unix())
+	} else if newHash != s.hash {
+		s.serial++
+	}
+	s.hash = newHash
+	s.byName = byName
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Notify requests an out-of-band refresh, e.g. in response to a NOTIFY
+// message, instead of waiting for the next refresh interval.
+func (s *Server) Notify() {
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the cache on RefreshInterval (and whenever Notify is called)
+// until ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.Refresh(ctx); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.notifyCh:
+		}
+		if err := s.Refresh(ctx); err != nil {
+			s.l.Error(err, "failed to refresh zone")
+		}
+	}
+}
+
+// ListenAndServe starts serving DNS queries for the zone on addr using the
+// given network ("udp" or "tcp").
+func (s *Server) ListenAndServe(addr string, network string) error {
+	mux := dns.NewServeMux()
+	mux.HandleFunc(s.origin, s.handleQuery)
+	srv := &dns.Server{Addr: addr, Net: network, Handler: mux}
+	return srv.ListenAndServe()
+}
+
+func (s *Server) handleQuery(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) != 1 {
+		dns.HandleFailed(w, r)
+		return
+	}
+	q := r.Question[0]
+
+	if q.Qtype == dns.TypeAXFR || q.Qtype == dns.TypeIXFR {
+		s.handleTransfer(w, r)
+		return
+	}
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	s.mu.RLock()
+	soa := s.soaRR()
+	if q.Qtype == dns.TypeSOA {
+		m.Answer = append(m.Answer, soa)
+	} else {
+		m.Answer = append(m.Answer, s.lookup(q.Name, q.Qtype)...)
+		if len(m.Answer) == 0 {
+			m.Ns = append(m.Ns, soa)
+		}
+	}
+	s.mu.RUnlock()
+
+	_ = w.WriteMsg(m)
+}
+
+func (s *Server) handleTransfer(w dns.ResponseWriter, r *dns.Msg) {
+	if s.allowedTransfer != nil {
+		host, _, _ := net.SplitHostPort(w.RemoteAddr().String())
+		if !s.allowedTransfer[host] {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeRefused)
+			_ = w.WriteMsg(m)
+			return
+		}
+	}
+
+	s.mu.RLock()
+	soa := s.soaRR()
+	var records []dns.RR
+	for _, rrs := range s.byName {
+		records = append(records, rrs...)
+	}
+	s.mu.RUnlock()
+
+	tr := new(dns.Transfer)
+	ch := make(chan *dns.Envelope)
+	go func() {
+		defer close(ch)
+		// dns.Transfer.Out does not split a single Envelope across multiple
+		// DNS messages, so a zone whose RRs don't fit in one ~64KB message
+		// must be handed over in several envelopes instead of one.
+		const maxRRsPerEnvelope = 500
+		ch <- &dns.Envelope{RR: []dns.RR{soa}}
+		for len(records) > 0 {
+			n := maxRRsPerEnvelope
+			if n > len(records) {
+				n = len(records)
+			}
+			ch <- &dns.Envelope{RR: append([]dns.RR(nil), records[:n]...)}
+			records = records[n:]
+		}
+		ch <- &dns.Envelope{RR: []dns.RR{soa}}
+	}()
+
+	if err := tr.Out(w, r, ch); err != nil {
+		s.l.Error(err, "AXFR transfer failed")
+	}
+	_ = w.Close()
+}
+
+func (s *Server) lookup(name string, qtype uint16) []dns.RR {
+	name = dns.Fqdn(name)
+	var out []dns.RR
+	for _, rr := range s.byName[name] {
+		if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+// soaRR builds a synthetic SOA for the zone using the current serial.
+// Callers must hold s.mu.
+func (s *Server) soaRR() *dns.SOA {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: s.origin, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+		Ns:      s.origin,
+		Mbox:    "hostmaster." + s.origin,
+		Serial:  s.serial,
+		Refresh: uint32(s.refreshInterval.Seconds()),
+		Retry:   900,
+		Expire:  604800,
+		Minttl:  300,
+	}
+}
+
+func sortedRecords(records []active24.DnsRecord) []active24.DnsRecord {
+	out := make([]active24.DnsRecord, len(records))
+	copy(out, records)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return recType(out[i]) < recType(out[j])
+	})
+	return out
+}
+
+func recType(rec active24.DnsRecord) string {
+	if rec.Type == nil {
+		return ""
+	}
+	return *rec.Type
+}
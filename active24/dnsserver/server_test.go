@@ -0,0 +1,179 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// fakeActions is a minimal in-memory active24.DnsRecordActions backed by a
+// fixed slice of records, enough to drive a Server end-to-end over real DNS
+// queries and AXFR transfers.
+type fakeActions struct {
+	active24.DnsRecordActions
+	records []active24.DnsRecord
+}
+
+func (f *fakeActions) ListAll() ([]active24.DnsRecord, active24.ApiError) {
+	return f.records, nil
+}
+
+func (f *fakeActions) ListAllCtx(_ context.Context) ([]active24.DnsRecord, active24.ApiError) {
+	return f.records, nil
+}
+
+func aRecord(name, recType, content string, ttl int) active24.DnsRecord {
+	t := recType
+	c := content
+	return active24.DnsRecord{Type: &t, Name: name, Content: &c, Ttl: ttl}
+}
+
+// startTestServer starts srv on a loopback UDP address and returns it
+// together with a func to shut it down.
+func startTestServer(t *testing.T, s *Server) (addr string, shutdown func()) {
+	t.Helper()
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %v", err)
+	}
+	mux := dns.NewServeMux()
+	mux.HandleFunc(s.origin, s.handleQuery)
+	dnsSrv := &dns.Server{PacketConn: pc, Handler: mux}
+	go func() { _ = dnsSrv.ActivateAndServe() }()
+
+	// ActivateAndServe returns once the listener is closed; give it a moment
+	// to actually start serving before the first query.
+	time.Sleep(50 * time.Millisecond)
+
+	return pc.LocalAddr().String(), func() { _ = dnsSrv.Shutdown() }
+}
+
+func TestHandleQueryAnswersA(t *testing.T) {
+	actions := &fakeActions{records: []active24.DnsRecord{
+		aRecord("www", string(active24.DnsRecordTypeA), "1.2.3.4", 300),
+	}}
+	s := New(actions, "example.com.")
+	addr, shutdown := startTestServer(t, s)
+	defer shutdown()
+
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.com.", dns.TypeA)
+	c := new(dns.Client)
+	resp, _, err := c.Exchange(m, addr)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if len(resp.Answer) != 1 {
+		t.Fatalf("Answer = %+v, want exactly one A record", resp.Answer)
+	}
+	a, ok := resp.Answer[0].(*dns.A)
+	if !ok {
+		t.Fatalf("Answer[0] = %T, want *dns.A", resp.Answer[0])
+	}
+	if a.A.String() != "1.2.3.4" {
+		t.Errorf("A = %s, want 1.2.3.4", a.A.String())
+	}
+}
+
+func TestHandleQueryNxReturnsSOAInAuthority(t *testing.T) {
+	actions := &fakeActions{}
+	s := New(actions, "example.com.")
+	addr, shutdown := startTestServer(t, s)
+	defer shutdown()
+
+	m := new(dns.Msg)
+	m.SetQuestion("missing.example.com.", dns.TypeA)
+	c := new(dns.Client)
+	resp, _, err := c.Exchange(m, addr)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if len(resp.Answer) != 0 {
+		t.Fatalf("Answer = %+v, want no answers", resp.Answer)
+	}
+	if len(resp.Ns) != 1 {
+		t.Fatalf("Ns = %+v, want the zone's SOA", resp.Ns)
+	}
+}
+
+func TestHandleTransferSendsAllRecordsAcrossEnvelopes(t *testing.T) {
+	var records []active24.DnsRecord
+	for i := 0; i < 1200; i++ {
+		records = append(records, aRecord("host"+strconv.Itoa(i), string(active24.DnsRecordTypeA), "1.2.3.4", 300))
+	}
+	actions := &fakeActions{records: records}
+	s := New(actions, "example.com.")
+
+	m := new(dns.Msg)
+	m.SetAxfr("example.com.")
+	tr := new(dns.Transfer)
+	// dns.Transfer requires a TCP connection; dial the server's TCP listener
+	// separately since startTestServer only opened a UDP socket.
+	tcpAddr, shutdownTCP := startAXFRListener(t, s)
+	defer shutdownTCP()
+
+	envelopes, err := tr.In(m, tcpAddr)
+	if err != nil {
+		t.Fatalf("tr.In: %v", err)
+	}
+	var got []dns.RR
+	envelopeCount := 0
+	for e := range envelopes {
+		if e.Error != nil {
+			t.Fatalf("envelope error: %v", e.Error)
+		}
+		envelopeCount++
+		got = append(got, e.RR...)
+	}
+	if envelopeCount < 5 {
+		t.Fatalf("got %d envelopes, want at least 5 (1200 records split at 500/envelope into 3, plus leading/trailing SOA)", envelopeCount)
+	}
+	// leading SOA + len(records) + trailing SOA
+	if want := len(records) + 2; len(got) != want {
+		t.Fatalf("got %d RRs across all envelopes, want %d", len(got), want)
+	}
+}
+
+func startAXFRListener(t *testing.T, s *Server) (addr string, shutdown func()) {
+	t.Helper()
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	mux := dns.NewServeMux()
+	mux.HandleFunc(s.origin, s.handleQuery)
+	dnsSrv := &dns.Server{Listener: ln, Handler: mux}
+	go func() { _ = dnsSrv.ActivateAndServe() }()
+	time.Sleep(50 * time.Millisecond)
+	return ln.Addr().String(), func() { _ = dnsSrv.Shutdown() }
+}
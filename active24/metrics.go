@@ -0,0 +1,168 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceEventKind identifies the kind of a TraceEvent.
+type TraceEventKind string
+
+const (
+	// TraceRequestStart fires right before a request is sent.
+	TraceRequestStart = TraceEventKind("RequestStart")
+	// TraceRequestEnd fires when a request (successful or not) completes.
+	TraceRequestEnd = TraceEventKind("RequestEnd")
+	// TraceRetry fires whenever a request is about to be retried.
+	TraceRetry = TraceEventKind("Retry")
+	// TracePageFetched fires once per List call, after its last page of
+	// records has been fetched; Attempt holds the number of pages fetched.
+	TracePageFetched = TraceEventKind("PageFetched")
+)
+
+// TraceEvent is emitted to a WithTracer hook so that callers can plug in
+// their own tracing (e.g. OpenTelemetry spans).
+type TraceEvent struct {
+	Kind       TraceEventKind
+	Method     string
+	Path       string
+	Attempt    int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+}
+
+// WithMetrics registers Prometheus counters/histograms for request count,
+// latency, status code, retries and pagination depth on reg, labelled by
+// method and an endpoint path template (e.g. "v2/service/:id/dns/record").
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(c *client) {
+		c.h.metrics = newMetricsCollector(reg)
+	}
+}
+
+// WithTracer registers fn to receive structured TraceEvents as requests are
+// made, retried, and paginated.
+func WithTracer(fn func(TraceEvent)) Option {
+	return func(c *client) {
+		c.h.tracer = fn
+	}
+}
+
+// WithHTTPClient lets callers inject their own *http.Client (e.g. to set a
+// custom Transport), instead of the one created internally by New.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *client) {
+		if hc != nil {
+			c.h.c = *hc
+		}
+	}
+}
+
+// WithWireDebug enables dumping of the raw HTTP request/response at klog
+// verbosity level 5. Disabled by default, since request/response bodies may
+// contain sensitive DNS record data.
+func WithWireDebug(enabled bool) Option {
+	return func(c *client) {
+		c.h.wireDebug = enabled
+	}
+}
+
+type metricsCollector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+	pages    *prometheus.HistogramVec
+}
+
+func newMetricsCollector(reg prometheus.Registerer) *metricsCollector {
+	m := &metricsCollector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "active24",
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "Total number of API requests, by method, endpoint and status code.",
+		}, []string{"method", "endpoint", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "active24",
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "API request latency in seconds, by method and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "active24",
+			Subsystem: "client",
+			Name:      "retries_total",
+			Help:      "Total number of retried API requests, by method and endpoint.",
+		}, []string{"method", "endpoint"}),
+		pages: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "active24",
+			Subsystem: "client",
+			Name:      "list_pages",
+			Help:      "Number of pages fetched per List call, by method and endpoint.",
+			Buckets:   prometheus.LinearBuckets(1, 1, 10),
+		}, []string{"method", "endpoint"}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.retries, m.pages)
+	return m
+}
+
+func (m *metricsCollector) observeRequest(method, endpoint, status string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, endpoint, status).Inc()
+	m.duration.WithLabelValues(method, endpoint).Observe(d.Seconds())
+}
+
+func (m *metricsCollector) observeRetry(method, endpoint string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(method, endpoint).Inc()
+}
+
+func (m *metricsCollector) observePages(method, endpoint string, pages int) {
+	if m == nil {
+		return
+	}
+	m.pages.WithLabelValues(method, endpoint).Observe(float64(pages))
+}
+
+// endpointTemplate collapses purely numeric path segments (service or record
+// IDs) in reqPath into ":id", so metrics don't acquire one label series per
+// distinct ID, e.g. "v2/service/12345/dns/record/678" becomes
+// "v2/service/:id/dns/record/:id".
+func endpointTemplate(reqPath string) string {
+	segments := strings.Split(reqPath, "/")
+	for i, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(seg); err == nil {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWithMetricsRecordsRequestsAndRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	c := New("key", "secret", ApiEndpoint(srv.URL), WithMetrics(reg), func(c *client) {
+		c.h.retry.MaxRetries = 1
+		c.h.retry.BaseDelay = 0
+	})
+
+	if apiErr := c.Dns().With(1).Create(&DnsRecord{Name: "www"}); apiErr != nil {
+		t.Fatalf("Create: %v", apiErr.Error())
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("server saw %d calls, want 2 (one 429 then one retry)", got)
+	}
+
+	retriesMetric := `
+# HELP active24_client_retries_total Total number of retried API requests, by method and endpoint.
+# TYPE active24_client_retries_total counter
+active24_client_retries_total{endpoint="v2/service/:id/dns/record",method="POST"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(retriesMetric), "active24_client_retries_total"); err != nil {
+		t.Errorf("unexpected retries metric: %v", err)
+	}
+}
+
+func TestWithTracerReceivesRequestAndRetryEvents(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var kinds []TraceEventKind
+	c := New("key", "secret", ApiEndpoint(srv.URL), WithTracer(func(ev TraceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		kinds = append(kinds, ev.Kind)
+	}), WithRetry(RetryPolicy{MaxRetries: 1}))
+
+	if apiErr := c.Dns().With(1).Create(&DnsRecord{Name: "www"}); apiErr != nil {
+		t.Fatalf("Create: %v", apiErr.Error())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var sawRetry bool
+	for _, k := range kinds {
+		if k == TraceRetry {
+			sawRetry = true
+		}
+	}
+	if !sawRetry {
+		t.Fatalf("trace events %v, want a TraceRetry event", kinds)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how doWithParamsCtx retries transient failures.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries attempted after the initial request fails.
+	// A value of 0 disables retrying.
+	MaxRetries int
+	// BaseDelay is the initial backoff delay, doubled on every subsequent attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay computed from BaseDelay.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by New unless overridden with WithRetry.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+}
+
+// WithRetry overrides the retry/backoff policy used when a request fails with
+// a transient error (429, 503, or a network error).
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.h.retry = policy
+	}
+}
+
+// WithRateLimit bounds outgoing requests to a token bucket of the given rate
+// (requests per second) and burst size.
+func WithRateLimit(rps int, burst int) Option {
+	return func(c *client) {
+		c.h.limiter = newRateLimiter(rps, burst)
+	}
+}
+
+// WithCircuitBreaker short-circuits requests with errCircuitOpen once
+// threshold consecutive requests have failed, until resetTimeout has elapsed
+// since the last failure.
+func WithCircuitBreaker(threshold int, resetTimeout time.Duration) Option {
+	return func(c *client) {
+		c.h.breaker = newCircuitBreaker(threshold, resetTimeout)
+	}
+}
+
+// errCircuitOpen is returned by doWithParamsCtx while the circuit breaker is open.
+var errCircuitOpen = errors.New("active24: circuit breaker open, too many consecutive failures")
+
+// rateLimiter is a minimal token-bucket limiter used to cap request rate.
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	burst  float64
+	rps    float64
+	last   time.Time
+}
+
+func newRateLimiter(rps, burst int) *rateLimiter {
+	if rps <= 0 {
+		rps = 1
+	}
+	if burst <= 0 {
+		burst = rps
+	}
+	return &rateLimiter{
+		tokens: float64(burst),
+		burst:  float64(burst),
+		rps:    float64(rps),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rps)
+		r.last = now
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - r.tokens) / r.rps * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// circuitBreaker opens after threshold consecutive failures and stays open
+// for resetTimeout before allowing a single probe request through.
+type circuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	resetTimeout time.Duration
+	consecutive  int
+	openedAt     time.Time
+}
+
+func newCircuitBreaker(threshold int, resetTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, resetTimeout: resetTimeout}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consecutive < b.threshold {
+		return true
+	}
+	if time.Since(b.openedAt) >= b.resetTimeout {
+		// half-open: let a single probe request through
+		b.consecutive = b.threshold - 1
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutive = 0
+}
+
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutive++
+	if b.consecutive >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// retryAfter parses the Retry-After header of resp, if present.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// backoff returns the delay before retry attempt n (0-based), using
+// exponential backoff with full jitter, capped at policy.MaxDelay.
+func backoff(policy RetryPolicy, n int) time.Duration {
+	if policy.BaseDelay <= 0 {
+		return 0
+	}
+	d := policy.BaseDelay * time.Duration(uint64(1)<<uint(n))
+	if d <= 0 || d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// isRetryableStatus reports whether an HTTP status code warrants a retry.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package active24
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(1000, 2)
+	ctx := context.Background()
+
+	// The burst should be available immediately, with no blocking.
+	start := time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected burst tokens to be consumed without blocking, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterWaitsForRefill(t *testing.T) {
+	l := newRateLimiter(100, 1)
+	ctx := context.Background()
+
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	start := time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Fatalf("expected second Wait to block for a refill, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterCtxCancelled(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	_ = l.Wait(context.Background()) // drain the single token
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to return an error for a cancelled context")
+	}
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(2, time.Hour)
+
+	if !b.allow() {
+		t.Fatal("expected breaker to allow requests before any failure")
+	}
+	b.recordFailure()
+	if !b.allow() {
+		t.Fatal("expected breaker to still allow requests below threshold")
+	}
+	b.recordFailure()
+	if b.allow() {
+		t.Fatal("expected breaker to be open once the threshold is reached")
+	}
+}
+
+func TestCircuitBreakerRecoversOnSuccess(t *testing.T) {
+	b := newCircuitBreaker(2, time.Hour)
+	b.recordFailure()
+	b.recordSuccess()
+	b.recordFailure()
+	if !b.allow() {
+		t.Fatal("expected recordSuccess to reset the consecutive failure count")
+	}
+}
+
+func TestCircuitBreakerHalfOpenAfterResetTimeout(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+	b.recordFailure()
+	if b.allow() {
+		t.Fatal("expected breaker to be open immediately after the threshold is reached")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("expected breaker to allow a single probe request once resetTimeout has elapsed")
+	}
+}
+
+func TestBackoffCapsAtMaxDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 2 * time.Second}
+	for n := 0; n < 10; n++ {
+		if d := backoff(policy, n); d > policy.MaxDelay {
+			t.Fatalf("backoff(%d) = %v, want <= %v", n, d, policy.MaxDelay)
+		}
+	}
+}
+
+func TestBackoffGrows(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: time.Hour}
+	// backoff jitters uniformly in [0, 2^n * BaseDelay], so a single draw says
+	// little. Attempt 3's ceiling is 8x attempt 0's, so over 100 draws at least
+	// one of them must exceed attempt 0's ceiling.
+	d0 := policy.BaseDelay * 1
+	var maxD3 time.Duration
+	for i := 0; i < 100; i++ {
+		if d := backoff(policy, 3); d > maxD3 {
+			maxD3 = d
+		}
+	}
+	if maxD3 <= d0 {
+		t.Fatalf("expected later attempts to have a larger backoff ceiling: max backoff(3) %v vs backoff(0) ceiling %v", maxD3, d0)
+	}
+	for n := 0; n < 5; n++ {
+		if d := backoff(policy, n); d < 0 {
+			t.Fatalf("backoff(%d) returned a negative duration: %v", n, d)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusInternalServerError, false},
+	}
+	for _, c := range cases {
+		if got := isRetryableStatus(c.code); got != c.want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", c.code, got, c.want)
+		}
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	d, ok := retryAfter(resp)
+	if !ok || d != 2*time.Second {
+		t.Fatalf("retryAfter() = %v, %v, want 2s, true", d, ok)
+	}
+}
+
+func TestRetryAfterMissing(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfter(resp); ok {
+		t.Fatal("expected retryAfter to report false when the header is absent")
+	}
+}
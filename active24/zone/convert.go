@@ -0,0 +1,206 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// relativeName strips origin from a fully-qualified owner name, returning
+// "@" for the zone apex, matching how Active24 represents record names.
+func relativeName(fqdn, origin string) string {
+	fqdn = dns.Fqdn(fqdn)
+	origin = dns.Fqdn(origin)
+	if fqdn == origin {
+		return "@"
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(fqdn, origin), ".")
+}
+
+// absoluteName expands an Active24 record name ("@", "", or relative) into a
+// fully-qualified owner name under origin.
+func absoluteName(name, origin string) string {
+	origin = dns.Fqdn(origin)
+	if name == "" || name == "@" {
+		return origin
+	}
+	return dns.Fqdn(name + "." + strings.TrimSuffix(origin, "."))
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func typePtr(t active24.DnsRecordType) *string {
+	s := string(t)
+	return &s
+}
+
+// fromRR converts a parsed zone file resource record into an active24.DnsRecord.
+// A nil record with a nil error means rr should be silently skipped (e.g. SOA,
+// which Active24 manages implicitly and never appears in DnsRecord).
+func fromRR(rr dns.RR, origin string) (*active24.DnsRecord, error) {
+	hdr := rr.Header()
+	name := relativeName(hdr.Name, origin)
+	ttl := int(hdr.Ttl)
+
+	switch v := rr.(type) {
+	case *dns.SOA, *dns.RRSIG, *dns.NSEC, *dns.NSEC3, *dns.DNSKEY:
+		return nil, nil
+	case *dns.A:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeA), Name: name, Ttl: ttl, Content: strPtr(v.A.String())}, nil
+	case *dns.AAAA:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeAAAA), Name: name, Ttl: ttl, Content: strPtr(v.AAAA.String())}, nil
+	case *dns.CNAME:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeCNAME), Name: name, Ttl: ttl, Content: strPtr(relativeTarget(v.Target, origin))}, nil
+	case *dns.NS:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeNS), Name: name, Ttl: ttl, Content: strPtr(relativeTarget(v.Ns, origin))}, nil
+	case *dns.MX:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeMX), Name: name, Ttl: ttl, Content: strPtr(relativeTarget(v.Mx, origin)), Priority: intPtr(int(v.Preference))}, nil
+	case *dns.SRV:
+		return &active24.DnsRecord{
+			Type: typePtr(active24.DnsRecordTypeSRV), Name: name, Ttl: ttl,
+			Content:  strPtr(relativeTarget(v.Target, origin)),
+			Priority: intPtr(int(v.Priority)), Weight: intPtr(int(v.Weight)), Port: intPtr(int(v.Port)),
+		}, nil
+	case *dns.TXT:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeTXT), Name: name, Ttl: ttl, Content: strPtr(joinTXT(v.Txt))}, nil
+	case *dns.CAA:
+		// Active24 has no dedicated CAA flag/tag fields, so flags+tag+value are
+		// packed into Content in the same textual form used in zone files.
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeCAA), Name: name, Ttl: ttl, Content: strPtr(fmt.Sprintf("%d %s %q", v.Flag, v.Tag, v.Value))}, nil
+	case *dns.SSHFP:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeSSHFP), Name: name, Ttl: ttl, Content: strPtr(fmt.Sprintf("%d %d %s", v.Algorithm, v.Type, strings.ToUpper(v.FingerPrint)))}, nil
+	case *dns.TLSA:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeTLSA), Name: name, Ttl: ttl, Content: strPtr(fmt.Sprintf("%d %d %d %s", v.Usage, v.Selector, v.MatchingType, strings.ToUpper(v.Certificate)))}, nil
+	case *dns.LOC:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeLOC), Name: name, Ttl: ttl, Content: strPtr(strings.TrimPrefix(v.String(), v.Hdr.String()))}, nil
+	case *dns.CERT:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeCERT), Name: name, Ttl: ttl, Content: strPtr(fmt.Sprintf("%d %d %d %s", v.Type, v.KeyTag, v.Algorithm, v.Certificate))}, nil
+	case *dns.DS:
+		return &active24.DnsRecord{Type: typePtr(active24.DnsRecordTypeDS), Name: name, Ttl: ttl, Content: strPtr(fmt.Sprintf("%d %d %d %s", v.KeyTag, v.Algorithm, v.DigestType, strings.ToUpper(v.Digest)))}, nil
+	default:
+		return nil, fmt.Errorf("active24/zone: unsupported record type %s for %s", dns.TypeToString[hdr.Rrtype], hdr.Name)
+	}
+}
+
+// toZoneLine renders rec as a single master zone file resource record line.
+func toZoneLine(rec active24.DnsRecord, origin string) (string, error) {
+	name := absoluteName(rec.Name, origin)
+	content := ""
+	if rec.Content != nil {
+		content = *rec.Content
+	}
+	t := recType(rec)
+
+	rdata, err := rdataFor(active24.DnsRecordType(t), content, rec, origin)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\t%d\tIN\t%s\t%s", name, rec.Ttl, t, rdata), nil
+}
+
+func rdataFor(t active24.DnsRecordType, content string, rec active24.DnsRecord, origin string) (string, error) {
+	switch t {
+	case active24.DnsRecordTypeA, active24.DnsRecordTypeAAAA:
+		return content, nil
+	case active24.DnsRecordTypeCNAME, active24.DnsRecordTypeNS:
+		return dns.Fqdn(absoluteTarget(content, origin)), nil
+	case active24.DnsRecordTypeMX:
+		return fmt.Sprintf("%d %s", intVal(rec.Priority), dns.Fqdn(absoluteTarget(content, origin))), nil
+	case active24.DnsRecordTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", intVal(rec.Priority), intVal(rec.Weight), intVal(rec.Port), dns.Fqdn(absoluteTarget(content, origin))), nil
+	case active24.DnsRecordTypeTXT:
+		return quoteTXT(content), nil
+	case active24.DnsRecordTypeCAA, active24.DnsRecordTypeSSHFP, active24.DnsRecordTypeTLSA,
+		active24.DnsRecordTypeDS, active24.DnsRecordTypeCERT, active24.DnsRecordTypeLOC:
+		// These already carry their full rdata (as packed in fromRR).
+		return content, nil
+	case active24.DnsRecordTypeANAME:
+		return "", fmt.Errorf("ANAME has no standard zone file representation")
+	default:
+		return "", fmt.Errorf("unsupported record type %s", t)
+	}
+}
+
+func intVal(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func relativeTarget(target, origin string) string {
+	return relativeName(target, origin)
+}
+
+func absoluteTarget(target, origin string) string {
+	if strings.HasSuffix(target, ".") {
+		return target
+	}
+	return absoluteName(target, origin)
+}
+
+// joinTXT mirrors how multiple quoted TXT strings are flattened into a single
+// content value; each chunk stays under the 255-byte RFC 1035 limit.
+func joinTXT(chunks []string) string {
+	return strings.Join(chunks, "")
+}
+
+// quoteTXT splits content back into ≤255 byte chunks and quotes/escapes them
+// the way a master zone file expects.
+func quoteTXT(content string) string {
+	const max = 255
+	var b strings.Builder
+	for len(content) > 0 {
+		n := max
+		if n > len(content) {
+			n = len(content)
+		}
+		chunk := content[:n]
+		content = content[n:]
+		b.WriteString(quoteTXTChunk(chunk))
+		if len(content) > 0 {
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
+
+// quoteTXTChunk escapes a TXT chunk using DNS zone-file TXT string rules
+// (backslash-escape '"' and '\\', wrap in double quotes).
+func quoteTXTChunk(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zone imports and exports the DNS records of a zone managed via
+// active24.DnsRecordActions to and from RFC 1035 master zone file format,
+// using github.com/miekg/dns for parsing/serialization.
+package zone
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/miekg/dns"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// ImportOptions controls how Import reconciles a parsed zone file against the
+// live zone.
+type ImportOptions struct {
+	// DryRun computes the Diff without applying it.
+	DryRun bool
+	// PruneUnknown deletes live records that are not present in the imported
+	// zone file. When false, such records are left untouched.
+	PruneUnknown bool
+	// Include, if non-empty, restricts the import to these record types.
+	Include []active24.DnsRecordType
+	// Exclude skips these record types, applied after Include.
+	Exclude []active24.DnsRecordType
+}
+
+// Diff is the set of changes Import computed between the imported zone file
+// and the live zone.
+type Diff struct {
+	Creates []active24.DnsRecord
+	Updates []active24.DnsRecord
+	Deletes []active24.DnsRecord
+}
+
+// Import parses the master zone file read from r and reconciles it against
+// the records currently present in actions, returning the Diff that was (or,
+// with ImportOptions.DryRun, would have been) applied. If a Create, Update or
+// Delete in the Diff fails partway through, Import compensates by reversing
+// the changes it already made (deleting the records it created, restoring
+// the previous TTL/priority/port/weight of the records it updated, and
+// recreating the records it deleted) before returning the original error, so
+// a single failure does not leave the zone half migrated. Compensation is
+// best-effort and not a true database transaction: the Active24 API does not
+// return the ID it assigns a newly created record, so undoing a Create
+// re-lists the zone and matches on (type, name, content) rather than ID, and
+// a record recreated to undo a Delete is assigned a new ID by the API.
+func Import(actions active24.DnsRecordActions, origin string, r io.Reader, opts ImportOptions) (Diff, active24.ApiError) {
+	return ImportCtx(context.Background(), actions, origin, r, opts)
+}
+
+// ImportCtx is like Import but honors ctx cancellation/deadline across the
+// potentially large number of sequential API calls a bulk import makes.
+func ImportCtx(ctx context.Context, actions active24.DnsRecordActions, origin string, r io.Reader, opts ImportOptions) (Diff, active24.ApiError) {
+	desired, err := Parse(origin, r)
+	if err != nil {
+		return Diff{}, apiErr(err)
+	}
+	desired = filterTypes(desired, opts.Include, opts.Exclude)
+
+	live, apiErr2 := actions.ListAllCtx(ctx)
+	if apiErr2 != nil {
+		return Diff{}, apiErr2
+	}
+
+	diff := computeDiff(filterTypes(live, opts.Include, opts.Exclude), desired, opts.PruneUnknown)
+	if opts.DryRun {
+		return diff, nil
+	}
+
+	liveByKey := make(map[string]active24.DnsRecord, len(live))
+	for _, rec := range live {
+		liveByKey[active24.RecordKey(rec)] = rec
+	}
+
+	var applied []appliedChange
+	for i := range diff.Creates {
+		if err := actions.CreateCtx(ctx, &diff.Creates[i]); err != nil {
+			return diff, rollback(ctx, actions, applied, err)
+		}
+		applied = append(applied, appliedChange{kind: changeCreate, rec: diff.Creates[i]})
+	}
+	for i := range diff.Updates {
+		prev := liveByKey[active24.RecordKey(diff.Updates[i])]
+		if err := actions.UpdateCtx(ctx, *diff.Updates[i].ID, &diff.Updates[i]); err != nil {
+			return diff, rollback(ctx, actions, applied, err)
+		}
+		applied = append(applied, appliedChange{kind: changeUpdate, rec: prev})
+	}
+	for i := range diff.Deletes {
+		if err := actions.DeleteCtx(ctx, *diff.Deletes[i].ID); err != nil {
+			return diff, rollback(ctx, actions, applied, err)
+		}
+		applied = append(applied, appliedChange{kind: changeDelete, rec: diff.Deletes[i]})
+	}
+	return diff, nil
+}
+
+// changeKind identifies which CRUD operation an appliedChange compensates
+// for.
+type changeKind int
+
+const (
+	changeCreate changeKind = iota
+	changeUpdate
+	changeDelete
+)
+
+// appliedChange records one change Import already made, and what's needed to
+// undo it: for changeCreate, the record as created (it has no ID yet); for
+// changeUpdate and changeDelete, the live record as it was before the change.
+type appliedChange struct {
+	kind changeKind
+	rec  active24.DnsRecord
+}
+
+// rollback undoes applied, most recent first, to compensate for origErr, and
+// returns origErr (wrapping any rollback failure) since that's always the
+// error Import propagates to its caller.
+func rollback(ctx context.Context, actions active24.DnsRecordActions, applied []appliedChange, origErr active24.ApiError) active24.ApiError {
+	for i := len(applied) - 1; i >= 0; i-- {
+		c := applied[i]
+		var rbErr active24.ApiError
+		switch c.kind {
+		case changeCreate:
+			rbErr = deleteByMatch(ctx, actions, c.rec)
+		case changeUpdate:
+			rec := c.rec
+			rbErr = actions.UpdateCtx(ctx, *rec.ID, &rec)
+		case changeDelete:
+			rec := c.rec
+			rec.ID = nil
+			rbErr = actions.CreateCtx(ctx, &rec)
+		}
+		if rbErr != nil {
+			return apiErr(fmt.Errorf("import failed (%w) and rollback also failed: %w", origErr.Error(), rbErr.Error()))
+		}
+	}
+	return origErr
+}
+
+// deleteByMatch re-lists the zone and deletes the record matching rec's
+// (type, name, content), used to undo a Create whose assigned ID the
+// Active24 API never returned to us.
+func deleteByMatch(ctx context.Context, actions active24.DnsRecordActions, rec active24.DnsRecord) active24.ApiError {
+	live, err := actions.ListAllCtx(ctx)
+	if err != nil {
+		return err
+	}
+	key := active24.RecordKey(rec)
+	for _, r := range live {
+		if r.ID != nil && active24.RecordKey(r) == key {
+			return actions.DeleteCtx(ctx, *r.ID)
+		}
+	}
+	return nil
+}
+
+// Export fetches every record from actions and serializes it to w as an RFC
+// 1035 master zone file, rooted at origin.
+func Export(actions active24.DnsRecordActions, origin string, w io.Writer) active24.ApiError {
+	return ExportCtx(context.Background(), actions, origin, w)
+}
+
+// ExportCtx is like Export but honors ctx cancellation/deadline.
+func ExportCtx(ctx context.Context, actions active24.DnsRecordActions, origin string, w io.Writer) active24.ApiError {
+	records, err := actions.ListAllCtx(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		line, convErr := toZoneLine(rec, origin)
+		if convErr != nil {
+			// Record types this package cannot faithfully round-trip (e.g.
+			// Active24's ANAME extension has no standard zone file form) are
+			// exported as a comment rather than silently dropped.
+			if _, werr := fmt.Fprintf(w, "; skipped %s %s: %v\n", recType(rec), rec.Name, convErr); werr != nil {
+				return apiErr(werr)
+			}
+			continue
+		}
+		if _, werr := fmt.Fprintln(w, line); werr != nil {
+			return apiErr(werr)
+		}
+	}
+	return nil
+}
+
+// Parse reads a master zone file from r and returns its records as
+// []active24.DnsRecord, rooted at origin.
+func Parse(origin string, r io.Reader) ([]active24.DnsRecord, error) {
+	zp := dns.NewZoneParser(r, dns.Fqdn(origin), "")
+	var out []active24.DnsRecord
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rec, err := fromRR(rr, origin)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			out = append(out, *rec)
+		}
+	}
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("active24/zone: error parsing zone file: %w", err)
+	}
+	return out, nil
+}
+
+func recType(rec active24.DnsRecord) string {
+	if rec.Type == nil {
+		return ""
+	}
+	return *rec.Type
+}
+
+func filterTypes(recs []active24.DnsRecord, include, exclude []active24.DnsRecordType) []active24.DnsRecord {
+	incSet := typeSet(include)
+	excSet := typeSet(exclude)
+	if len(incSet) == 0 && len(excSet) == 0 {
+		return recs
+	}
+	var out []active24.DnsRecord
+	for _, rec := range recs {
+		t := active24.DnsRecordType(recType(rec))
+		if len(incSet) > 0 && !incSet[t] {
+			continue
+		}
+		if excSet[t] {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func typeSet(types []active24.DnsRecordType) map[active24.DnsRecordType]bool {
+	set := make(map[active24.DnsRecordType]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return set
+}
+
+func computeDiff(live, desired []active24.DnsRecord, pruneUnknown bool) Diff {
+	liveByKey := make(map[string]active24.DnsRecord, len(live))
+	for _, rec := range live {
+		liveByKey[active24.RecordKey(rec)] = rec
+	}
+	seen := make(map[string]bool, len(desired))
+
+	var diff Diff
+	for _, rec := range desired {
+		key := active24.RecordKey(rec)
+		seen[key] = true
+		if existing, ok := liveByKey[key]; ok {
+			if !active24.RecordsEqual(existing, rec) {
+				rec.ID = existing.ID
+				diff.Updates = append(diff.Updates, rec)
+			}
+			continue
+		}
+		diff.Creates = append(diff.Creates, rec)
+	}
+
+	if pruneUnknown {
+		for key, rec := range liveByKey {
+			if !seen[key] {
+				diff.Deletes = append(diff.Deletes, rec)
+			}
+		}
+	}
+	return diff
+}
+
+func apiErr(err error) active24.ApiError {
+	if err == nil {
+		return nil
+	}
+	return &simpleApiError{err: err}
+}
+
+// simpleApiError adapts a plain error to active24.ApiError for errors raised
+// by this package (zone file parsing/serialization) rather than the API.
+type simpleApiError struct {
+	err error
+}
+
+func (e *simpleApiError) Error() error {
+	return e.err
+}
+
+func (e *simpleApiError) Response() *http.Response {
+	return nil
+}
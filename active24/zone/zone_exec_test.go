@@ -0,0 +1,178 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// fakeRecordServer is a minimal in-memory stand-in for the Active24 DNS
+// record API, enough to drive ImportCtx/ExportCtx end-to-end over real HTTP.
+type fakeRecordServer struct {
+	mu      sync.Mutex
+	nextID  int
+	records map[int]active24.DnsRecord
+	srv     *httptest.Server
+}
+
+func newFakeRecordServer() *fakeRecordServer {
+	f := &fakeRecordServer{records: map[int]active24.DnsRecord{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeRecordServer) URL() string { return f.srv.URL }
+func (f *fakeRecordServer) Close()      { f.srv.Close() }
+
+func (f *fakeRecordServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet:
+		data := make([]active24.DnsRecord, 0, len(f.records))
+		for _, rec := range f.records {
+			data = append(data, rec)
+		}
+		page, pages := 1, 1
+		_ = json.NewEncoder(w).Encode(active24.DnsRecordPaginatedCollection{
+			CurrentPage: &page, TotalPages: &pages, Data: data,
+		})
+	case r.Method == http.MethodPost:
+		var rec active24.DnsRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		f.nextID++
+		id := f.nextID
+		rec.ID = &id
+		f.records[id] = rec
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		id := idFromPath(r.URL.Path)
+		var rec active24.DnsRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		rec.ID = &id
+		f.records[id] = rec
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		id := idFromPath(r.URL.Path)
+		delete(f.records, id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// idFromPath extracts the trailing record ID from a path of the form
+// "/v2/service/{svcID}/dns/record/{id}".
+func idFromPath(path string) int {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	id, _ := strconv.Atoi(segments[len(segments)-1])
+	return id
+}
+
+func (f *fakeRecordServer) seed(recs ...active24.DnsRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, rec := range recs {
+		f.nextID++
+		id := f.nextID
+		rec.ID = &id
+		f.records[id] = rec
+	}
+}
+
+func (f *fakeRecordServer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.records)
+}
+
+func newTestActions(f *fakeRecordServer) active24.DnsRecordActions {
+	c := active24.New("key", "secret", active24.ApiEndpoint(f.URL()))
+	return c.Dns().With(1)
+}
+
+func TestImportCtxCreatesMissingRecord(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	actions := newTestActions(f)
+
+	diff, apiErr := ImportCtx(context.Background(), actions, testOrigin, strings.NewReader(testZone), ImportOptions{})
+	if apiErr != nil {
+		t.Fatalf("ImportCtx: %v", apiErr.Error())
+	}
+	if len(diff.Creates) == 0 {
+		t.Fatalf("diff.Creates is empty, want the records parsed from testZone")
+	}
+	if got, want := f.count(), len(diff.Creates); got != want {
+		t.Fatalf("server has %d records, want %d", got, want)
+	}
+}
+
+func TestImportCtxPruneUnknownDeletesStaleRecord(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	typ := string(active24.DnsRecordTypeA)
+	stale := "9.9.9.9"
+	f.seed(active24.DnsRecord{Type: &typ, Name: "stale", Content: &stale, Ttl: 300})
+	actions := newTestActions(f)
+
+	diff, apiErr := ImportCtx(context.Background(), actions, testOrigin, strings.NewReader(testZone), ImportOptions{PruneUnknown: true})
+	if apiErr != nil {
+		t.Fatalf("ImportCtx: %v", apiErr.Error())
+	}
+	if len(diff.Deletes) != 1 || diff.Deletes[0].Name != "stale" {
+		t.Fatalf("diff.Deletes = %+v, want the stale record", diff.Deletes)
+	}
+
+	live, apiErr := actions.ListAll()
+	if apiErr != nil {
+		t.Fatalf("ListAll: %v", apiErr.Error())
+	}
+	for _, rec := range live {
+		if rec.Name == "stale" {
+			t.Fatalf("stale record survived ImportCtx with PruneUnknown, live = %+v", live)
+		}
+	}
+}
+
+func TestExportCtxRoundTripsThroughImportCtx(t *testing.T) {
+	f := newFakeRecordServer()
+	defer f.Close()
+	actions := newTestActions(f)
+
+	if _, apiErr := ImportCtx(context.Background(), actions, testOrigin, strings.NewReader(testZone), ImportOptions{}); apiErr != nil {
+		t.Fatalf("ImportCtx: %v", apiErr.Error())
+	}
+
+	var buf strings.Builder
+	if apiErr := ExportCtx(context.Background(), actions, testOrigin, &buf); apiErr != nil {
+		t.Fatalf("ExportCtx: %v", apiErr.Error())
+	}
+	if !strings.Contains(buf.String(), "txt") {
+		t.Fatalf("exported zone file missing the txt record:\n%s", buf.String())
+	}
+}
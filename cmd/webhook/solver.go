@@ -0,0 +1,182 @@
+/*
+Copyright 2023 Richard Kosegi
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cert-manager/cert-manager/pkg/acme/webhook/apis/acme/v1alpha1"
+	extapi "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/hostalp/active24-go/active24"
+)
+
+// active24DNSProviderConfig is the configuration carried in a
+// ChallengeRequest's Config field, as specified on the ClusterIssuer/Issuer
+// webhook solver.
+type active24DNSProviderConfig struct {
+	// ServiceID is the Active24 service ID of the zone this config applies to.
+	// The Active24 API has no endpoint to list zones/services or resolve one
+	// by name, so unlike the acme.DNSProvider's DomainServiceIDs map (which at
+	// least derives the service ID from a configured zone name), this webhook
+	// solver has no way to discover it either: the operator must look it up
+	// out of band and hardcode it per Issuer/ClusterIssuer.
+	ServiceID int `json:"serviceID"`
+	// ApiEndpoint overrides the default Active24 REST API endpoint, if set.
+	ApiEndpoint string `json:"apiEndpoint,omitempty"`
+	// Ttl is the TTL (in seconds) used for the TXT records created by this solver.
+	Ttl int `json:"ttl,omitempty"`
+	// ApiKeySecretRef references the Kubernetes Secret key holding the Active24 API key.
+	ApiKeySecretRef corev1SecretKeyRef `json:"apiKeySecretRef"`
+	// ApiSecretSecretRef references the Kubernetes Secret key holding the Active24 API secret.
+	ApiSecretSecretRef corev1SecretKeyRef `json:"apiSecretSecretRef"`
+}
+
+// corev1SecretKeyRef is a (name, key) reference into a Kubernetes Secret
+// living in the same namespace as the ChallengeRequest.
+type corev1SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// active24DNSProviderSolver implements the cert-manager webhook.Solver
+// interface on top of the Active24 DNS record API.
+type active24DNSProviderSolver struct {
+	client kubernetes.Interface
+}
+
+func (s *active24DNSProviderSolver) Name() string {
+	return "active24"
+}
+
+func (s *active24DNSProviderSolver) Initialize(kubeClientConfig *rest.Config, stopCh <-chan struct{}) error {
+	cl, err := kubernetes.NewForConfig(kubeClientConfig)
+	if err != nil {
+		return err
+	}
+	s.client = cl
+	return nil
+}
+
+func (s *active24DNSProviderSolver) Present(ch *v1alpha1.ChallengeRequest) error {
+	actions, recName, err := s.dnsRecordActions(ch)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(ch.Config)
+	if err != nil {
+		return err
+	}
+
+	recType := string(active24.DnsRecordTypeTXT)
+	content := ch.Key
+	ttl := cfg.Ttl
+	if ttl == 0 {
+		ttl = 600
+	}
+	if apiErr := actions.Create(&active24.DnsRecord{
+		Type:    &recType,
+		Name:    recName,
+		Content: &content,
+		Ttl:     ttl,
+	}); apiErr != nil {
+		return fmt.Errorf("active24-webhook: failed to create TXT record %q: %w", ch.ResolvedFQDN, apiErr.Error())
+	}
+	return nil
+}
+
+func (s *active24DNSProviderSolver) CleanUp(ch *v1alpha1.ChallengeRequest) error {
+	actions, recName, err := s.dnsRecordActions(ch)
+	if err != nil {
+		return err
+	}
+
+	recs, apiErr := actions.List(active24.DnsRecordTypeTXT, recName)
+	if apiErr != nil {
+		return fmt.Errorf("active24-webhook: failed to list TXT records %q: %w", ch.ResolvedFQDN, apiErr.Error())
+	}
+	for _, rec := range recs {
+		if rec.Content != nil && *rec.Content == ch.Key && rec.ID != nil {
+			if apiErr := actions.Delete(*rec.ID); apiErr != nil {
+				return fmt.Errorf("active24-webhook: failed to delete TXT record %q: %w", ch.ResolvedFQDN, apiErr.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// dnsRecordActions builds a DnsRecordActions client for the zone referenced
+// by ch's config and returns it together with the record name (relative to
+// that zone) to present/clean up.
+func (s *active24DNSProviderSolver) dnsRecordActions(ch *v1alpha1.ChallengeRequest) (active24.DnsRecordActions, string, error) {
+	cfg, err := loadConfig(ch.Config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey, err := s.secretValue(ch.ResourceNamespace, cfg.ApiKeySecretRef)
+	if err != nil {
+		return nil, "", fmt.Errorf("active24-webhook: failed to load API key: %w", err)
+	}
+	apiSecret, err := s.secretValue(ch.ResourceNamespace, cfg.ApiSecretSecretRef)
+	if err != nil {
+		return nil, "", fmt.Errorf("active24-webhook: failed to load API secret: %w", err)
+	}
+
+	var opts []active24.Option
+	if cfg.ApiEndpoint != "" {
+		opts = append(opts, active24.ApiEndpoint(cfg.ApiEndpoint))
+	}
+	client := active24.New(apiKey, apiSecret, opts...)
+
+	recName := strings.TrimSuffix(strings.TrimSuffix(ch.ResolvedFQDN, "."), "."+strings.TrimSuffix(ch.ResolvedZone, "."))
+	recName = strings.TrimSuffix(recName, ".")
+
+	return client.Dns().With(cfg.ServiceID), recName, nil
+}
+
+// secretValue fetches a single key out of a Kubernetes Secret.
+func (s *active24DNSProviderSolver) secretValue(namespace string, ref corev1SecretKeyRef) (string, error) {
+	secret, err := s.client.CoreV1().Secrets(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %q/%q", ref.Key, namespace, ref.Name)
+	}
+	return string(data), nil
+}
+
+// loadConfig decodes the webhook solver config carried on the ChallengeRequest.
+func loadConfig(cfgJSON *extapi.JSON) (active24DNSProviderConfig, error) {
+	cfg := active24DNSProviderConfig{}
+	if cfgJSON == nil {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(cfgJSON.Raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("active24-webhook: error decoding solver config: %w", err)
+	}
+	return cfg, nil
+}